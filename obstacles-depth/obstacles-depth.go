@@ -5,16 +5,23 @@
 package obstaclesdepth
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"image"
+	"math"
 	"sort"
+	"sync"
+	"time"
 
 	"github.com/golang/geo/r3"
 	"github.com/pkg/errors"
 	"go.opencensus.io/trace"
 
 	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/movementsensor"
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/pointcloud"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/rimage"
 	"go.viam.com/rdk/rimage/depthadapter"
@@ -32,6 +39,13 @@ import (
 var Model = resource.NewModel("viam", "vision", "obstacles-depth")
 var errUnimplemented = errors.New("obstacles depth service does not implement this method")
 
+// obstacleLabel is the class label attached to every 2D detection produced from a depth cluster.
+const obstacleLabel = "obstacle"
+
+// obstacleConfidenceDenom scales cluster point count into a [0, 1] confidence score. Clusters
+// with at least this many points are reported at full confidence.
+const obstacleConfidenceDenom = 200.0
+
 func init() {
 	resource.RegisterService(vision.API, Model, resource.Registration[vision.Service, *ObsDepthConfig]{
 		Constructor: func(
@@ -51,6 +65,9 @@ func init() {
 
 // ObsDepthConfig specifies the parameters to be used for the obstacle depth service.
 type ObsDepthConfig struct {
+	// MinPtsInPlane through AngleTolerance are the ERCCL parameters. They are read directly here
+	// for backwards compatibility with existing configs; new configs should prefer the nested
+	// ERCCL block below, which takes precedence when both are present.
 	MinPtsInPlane        int     `json:"min_points_in_plane"`
 	MinPtsInSegment      int     `json:"min_points_in_segment"`
 	MaxDistFromPlane     float64 `json:"max_dist_from_plane_mm"`
@@ -58,17 +75,115 @@ type ObsDepthConfig struct {
 	ClusteringStrictness float64 `json:"clustering_strictness"`
 	AngleTolerance       float64 `json:"ground_angle_tolerance_degs"`
 	DefaultCamera        string  `json:"camera_name"`
+
+	// Algorithm selects the segmentation backend used to cluster the projected point cloud. One
+	// of "erccl" (default), "radius", "euclidean", or "dbscan".
+	Algorithm string `json:"algorithm"`
+	// ERCCL configures the "erccl" algorithm. If omitted, the flat fields above are used instead.
+	ERCCL *ERCCLSubConfig `json:"erccl"`
+	// Radius configures the "radius" algorithm. Required when algorithm is "radius".
+	Radius *RadiusClusteringConfig `json:"radius"`
+	// Euclidean configures the "euclidean" algorithm. Required when algorithm is "euclidean".
+	Euclidean *EuclideanClusteringConfig `json:"euclidean"`
+	// DBSCAN configures the "dbscan" algorithm. Required when algorithm is "dbscan".
+	DBSCAN *DBSCANConfig `json:"dbscan"`
+
+	// OrientationSensor is an optional movementsensor.MovementSensor dependency used to keep the
+	// ERCCL ground-plane normal accurate as the camera's pitch/roll changes (e.g. a rover on a
+	// slope, or an arm-mounted depth camera). If unset, obstacles_depth falls back to assuming
+	// the camera's Y axis always points straight down in the world frame.
+	OrientationSensor string `json:"orientation_sensor"`
+	// CameraToSensor is the static transform from the camera's frame to the orientation sensor's
+	// frame. It is composed with the sensor's live orientation reading on every call. Defaults to
+	// the identity pose (sensor co-located and coaxial with the camera). Ignored unless
+	// OrientationSensor is set.
+	CameraToSensor *spatialmath.PoseConfig `json:"camera_to_sensor"`
+
+	// Tracking enables a temporal tracking layer on top of segmentation. Unset, or
+	// {"enabled": false}, leaves obstacles_depth's existing stateless, per-frame behavior
+	// unchanged.
+	Tracking *TrackingConfig `json:"tracking"`
+
+	// ROI restricts returned obstacles to those centered inside a box in the camera frame. Unset
+	// returns every obstacle, as before. The median-depth point returned when the camera has no
+	// intrinsics is not filtered.
+	ROI *ROIConfig `json:"roi"`
+}
+
+// ERCCLSubConfig holds the same fields as the top-level ERCCL fields of ObsDepthConfig, for users
+// who configure the "erccl" algorithm with an explicit `"erccl"` block.
+type ERCCLSubConfig struct {
+	MinPtsInPlane        int     `json:"min_points_in_plane"`
+	MinPtsInSegment      int     `json:"min_points_in_segment"`
+	MaxDistFromPlane     float64 `json:"max_dist_from_plane_mm"`
+	ClusteringRadius     int     `json:"clustering_radius"`
+	ClusteringStrictness float64 `json:"clustering_strictness"`
+	AngleTolerance       float64 `json:"ground_angle_tolerance_degs"`
 }
 
 // obsDepth is the underlying struct actually used by the service.
 type obsDepth struct {
 	resource.AlwaysRebuild
-	clusteringConf *segmentation.ErCCLConfig
-	intrinsics     *transform.PinholeCameraIntrinsics
-	deps           resource.Dependencies
-	logger         logging.Logger
-	name           resource.Name
-	defaultCamera  camera.Camera
+	deps              resource.Dependencies
+	logger            logging.Logger
+	name              resource.Name
+	defaultCamera     camera.Camera
+	orientationSensor movementsensor.MovementSensor
+	cameraToSensor    spatialmath.Pose
+
+	// tracker is non-nil only when tracking is enabled; trackingConf is its accompanying config.
+	// Neither changes after construction, so no mutex is needed to read them.
+	tracker      *tracker
+	trackingConf *TrackingConfig
+
+	// roi is nil unless configured; it never changes after construction.
+	roi *ROIConfig
+
+	// confMu guards segmenter, which DoCommand can update live (for the erccl algorithm) without
+	// a reconfigure.
+	confMu    sync.Mutex
+	segmenter Segmenter
+
+	// intrinsicsMu guards intrinsics, which buildObsDepth discovers lazily on the first call
+	// against a real camera and which GetProperties/Detections/DetectionsFromCamera can read
+	// concurrently from other goroutines.
+	intrinsicsMu sync.Mutex
+	intrinsics   *transform.PinholeCameraIntrinsics
+
+	// lastMu guards the diagnostic snapshot taken after the most recent GetObjectPointClouds call.
+	lastMu     sync.Mutex
+	lastCloud  pointcloud.PointCloud
+	lastObject []*vis.Object
+}
+
+// getSegmenter returns the segmentation backend to use for the next call, synchronized against a
+// concurrent DoCommand "set". When the backend is the erccl algorithm, its ErCCLConfig is cloned
+// while confMu is held so the caller gets an isolated snapshot instead of a pointer that a
+// concurrent "set" could mutate out from under it.
+func (o *obsDepth) getSegmenter() Segmenter {
+	o.confMu.Lock()
+	defer o.confMu.Unlock()
+	if erccl, ok := o.segmenter.(*ercclSegmenter); ok {
+		confCopy := *erccl.conf
+		return &ercclSegmenter{conf: &confCopy}
+	}
+	return o.segmenter
+}
+
+// setIntrinsics stores the camera intrinsics discovered on a GetObjectPointClouds call, guarding
+// against concurrent reads from GetProperties/Detections/DetectionsFromCamera.
+func (o *obsDepth) setIntrinsics(in *transform.PinholeCameraIntrinsics) {
+	o.intrinsicsMu.Lock()
+	o.intrinsics = in
+	o.intrinsicsMu.Unlock()
+}
+
+// getIntrinsics returns the most recently discovered camera intrinsics, or nil if none have been
+// discovered yet.
+func (o *obsDepth) getIntrinsics() *transform.PinholeCameraIntrinsics {
+	o.intrinsicsMu.Lock()
+	defer o.intrinsicsMu.Unlock()
+	return o.intrinsics
 }
 
 func (cfg *ObsDepthConfig) Validate(path string) ([]string, []string, error) {
@@ -78,6 +193,19 @@ func (cfg *ObsDepthConfig) Validate(path string) ([]string, []string, error) {
 	if cfg.DefaultCamera != "" {
 		reqDeps = append(reqDeps, cfg.DefaultCamera)
 	}
+	if cfg.OrientationSensor != "" {
+		reqDeps = append(reqDeps, cfg.OrientationSensor)
+	}
+	if cfg.Tracking != nil && cfg.Tracking.Enabled {
+		if err := cfg.Tracking.CheckValid(); err != nil {
+			return nil, nil, errors.Wrap(err, "invalid tracking config")
+		}
+	}
+	if cfg.ROI != nil {
+		if err := cfg.ROI.CheckValid(); err != nil {
+			return nil, nil, errors.Wrap(err, "invalid roi config")
+		}
+	}
 
 	return reqDeps, optDeps, nil
 }
@@ -95,19 +223,9 @@ func registerObstaclesDepth(
 		return nil, errors.New("config for obstacles_depth cannot be nil")
 	}
 
-	// build the clustering config
-	cfg := &segmentation.ErCCLConfig{
-		MinPtsInPlane:        conf.MinPtsInPlane,
-		MinPtsInSegment:      conf.MinPtsInSegment,
-		MaxDistFromPlane:     conf.MaxDistFromPlane,
-		NormalVec:            r3.Vector{X: 0, Y: -1, Z: 0},
-		AngleTolerance:       conf.AngleTolerance,
-		ClusteringRadius:     conf.ClusteringRadius,
-		ClusteringStrictness: conf.ClusteringStrictness,
-	}
-	err := cfg.CheckValid()
+	segmenter, err := buildSegmenter(conf)
 	if err != nil {
-		return nil, errors.Wrap(err, "error building clustering config for obstacles_depth")
+		return nil, err
 	}
 
 	// Get camera dependency if specified
@@ -119,17 +237,106 @@ func registerObstaclesDepth(
 		}
 	}
 
+	// Get orientation sensor dependency if specified
+	var orientationSensor movementsensor.MovementSensor
+	cameraToSensor := spatialmath.NewZeroPose()
+	if conf.OrientationSensor != "" {
+		orientationSensor, err = movementsensor.FromDependencies(deps, conf.OrientationSensor)
+		if err != nil {
+			return nil, errors.Errorf("could not find orientation sensor %q", conf.OrientationSensor)
+		}
+		if conf.CameraToSensor != nil {
+			cameraToSensor, err = conf.CameraToSensor.Pose()
+			if err != nil {
+				return nil, errors.Wrap(err, "invalid camera_to_sensor transform for obstacles_depth")
+			}
+		}
+	}
+
+	var obsTracker *tracker
+	if conf.Tracking != nil && conf.Tracking.Enabled {
+		obsTracker = newTracker()
+	}
+
 	myObsDep := &obsDepth{
-		clusteringConf: cfg,
-		deps:           deps,
-		logger:         logger,
-		name:           name,
-		defaultCamera:  defaultCam,
+		segmenter:         segmenter,
+		deps:              deps,
+		logger:            logger,
+		name:              name,
+		defaultCamera:     defaultCam,
+		orientationSensor: orientationSensor,
+		cameraToSensor:    cameraToSensor,
+		tracker:           obsTracker,
+		trackingConf:      conf.Tracking,
+		roi:               conf.ROI,
 	}
 
 	return myObsDep, nil
 }
 
+// buildSegmenter constructs the Segmenter named by conf.Algorithm (defaulting to "erccl"),
+// validating its sub-config along the way.
+func buildSegmenter(conf *ObsDepthConfig) (Segmenter, error) {
+	algorithm := conf.Algorithm
+	if algorithm == "" {
+		algorithm = "erccl"
+	}
+
+	switch algorithm {
+	case "erccl":
+		ercclConf := conf.ERCCL
+		if ercclConf == nil {
+			ercclConf = &ERCCLSubConfig{
+				MinPtsInPlane:        conf.MinPtsInPlane,
+				MinPtsInSegment:      conf.MinPtsInSegment,
+				MaxDistFromPlane:     conf.MaxDistFromPlane,
+				ClusteringRadius:     conf.ClusteringRadius,
+				ClusteringStrictness: conf.ClusteringStrictness,
+				AngleTolerance:       conf.AngleTolerance,
+			}
+		}
+		cfg := &segmentation.ErCCLConfig{
+			MinPtsInPlane:        ercclConf.MinPtsInPlane,
+			MinPtsInSegment:      ercclConf.MinPtsInSegment,
+			MaxDistFromPlane:     ercclConf.MaxDistFromPlane,
+			NormalVec:            r3.Vector{X: 0, Y: -1, Z: 0},
+			AngleTolerance:       ercclConf.AngleTolerance,
+			ClusteringRadius:     ercclConf.ClusteringRadius,
+			ClusteringStrictness: ercclConf.ClusteringStrictness,
+		}
+		if err := cfg.CheckValid(); err != nil {
+			return nil, errors.Wrap(err, "error building clustering config for obstacles_depth")
+		}
+		return &ercclSegmenter{conf: cfg}, nil
+	case "radius":
+		if conf.Radius == nil {
+			return nil, errors.New("algorithm \"radius\" requires a \"radius\" config block")
+		}
+		if err := conf.Radius.CheckValid(); err != nil {
+			return nil, errors.Wrap(err, "invalid radius clustering config")
+		}
+		return &radiusSegmenter{conf: conf.Radius}, nil
+	case "euclidean":
+		if conf.Euclidean == nil {
+			return nil, errors.New("algorithm \"euclidean\" requires a \"euclidean\" config block")
+		}
+		if err := conf.Euclidean.CheckValid(); err != nil {
+			return nil, errors.Wrap(err, "invalid euclidean clustering config")
+		}
+		return &euclideanSegmenter{conf: conf.Euclidean}, nil
+	case "dbscan":
+		if conf.DBSCAN == nil {
+			return nil, errors.New("algorithm \"dbscan\" requires a \"dbscan\" config block")
+		}
+		if err := conf.DBSCAN.CheckValid(); err != nil {
+			return nil, errors.Wrap(err, "invalid dbscan config")
+		}
+		return &dbscanSegmenter{conf: conf.DBSCAN}, nil
+	default:
+		return nil, errors.Errorf("unknown obstacles_depth algorithm %q: must be one of erccl, radius, euclidean, dbscan", algorithm)
+	}
+}
+
 // BuildObsDepth will check for intrinsics and determine how to build based on that.
 func (o *obsDepth) buildObsDepth(logger logging.Logger) func(
 	ctx context.Context, src camera.Camera) ([]*vis.Object, error) {
@@ -143,7 +350,7 @@ func (o *obsDepth) buildObsDepth(logger logging.Logger) func(
 			logger.CWarn(ctx, "obstacles depth started but camera did not have intrinsic parameters")
 			return o.obsDepthNoIntrinsics(ctx, src)
 		}
-		o.intrinsics = props.IntrinsicParams
+		o.setIntrinsics(props.IntrinsicParams)
 		return o.obsDepthWithIntrinsics(ctx, src)
 	}
 }
@@ -177,7 +384,8 @@ func (o *obsDepth) obsDepthNoIntrinsics(ctx context.Context, src camera.Camera)
 // before clustering and projecting those points into 3D obstacles.
 func (o *obsDepth) obsDepthWithIntrinsics(ctx context.Context, src camera.Camera) ([]*vis.Object, error) {
 	// Check if we have intrinsics here. If not, don't even try
-	if o.intrinsics == nil {
+	intrinsics := o.getIntrinsics()
+	if intrinsics == nil {
 		return nil, errors.New("tried to build obstacles depth with intrinsics but no instrinsics found")
 	}
 	img, err := camera.DecodeImageFromCamera(ctx, "", nil, src)
@@ -188,8 +396,74 @@ func (o *obsDepth) obsDepthWithIntrinsics(ctx context.Context, src camera.Camera
 	if err != nil {
 		return nil, err
 	}
-	cloud := depthadapter.ToPointCloud(dm, o.intrinsics)
-	return segmentation.ApplyERCCLToPointCloud(ctx, cloud, o.clusteringConf)
+	cloud := depthadapter.ToPointCloud(dm, intrinsics)
+
+	objects, err := o.segment(ctx, cloud)
+	if err != nil {
+		return nil, err
+	}
+	if o.tracker != nil {
+		objects = o.tracker.Update(objects, time.Now(), o.trackingConf.maxAge(), o.trackingConf.IoUThreshold)
+	}
+	objects = filterByROI(objects, o.roi)
+	o.lastMu.Lock()
+	o.lastCloud = cloud
+	o.lastObject = objects
+	o.lastMu.Unlock()
+	return objects, nil
+}
+
+// segment runs cloud through the configured segmentation backend. It is shared by every entry
+// point that produces objects or detections from a point cloud; unlike obsDepthWithIntrinsics, it
+// does not apply the temporal tracker, since the tracker's state belongs to the continuously
+// polled camera stream and must not be perturbed by one-off calls against an arbitrary image (see
+// Detections). The ROI filter, which is stateless, is still the caller's responsibility to apply.
+func (o *obsDepth) segment(ctx context.Context, cloud pointcloud.PointCloud) ([]*vis.Object, error) {
+	// getSegmenter already hands back an isolated clone for the erccl algorithm, so it's safe to
+	// mutate its config in place here without racing a concurrent DoCommand "set".
+	segmenter := o.getSegmenter()
+	if erccl, ok := segmenter.(*ercclSegmenter); ok {
+		erccl.conf.NormalVec = o.computeNormalVec(ctx)
+	}
+	return segmenter.Segment(ctx, cloud)
+}
+
+// defaultGroundNormal is the static ground-plane normal assumed when no orientation sensor is
+// configured or its reading can't be trusted: the camera's Y axis pointing straight down.
+var defaultGroundNormal = r3.Vector{X: 0, Y: -1, Z: 0}
+
+// computeNormalVec returns the ground-plane normal, in camera frame, to use for this call's
+// ERCCL segmentation. If an orientation sensor is configured, world-down is rotated into the
+// camera frame using the sensor's latest orientation reading composed with the static
+// camera-to-sensor transform; otherwise, or if the reading fails, it falls back to
+// defaultGroundNormal.
+func (o *obsDepth) computeNormalVec(ctx context.Context) r3.Vector {
+	if o.orientationSensor == nil {
+		return defaultGroundNormal
+	}
+	orientation, err := o.orientationSensor.Orientation(ctx, nil)
+	if err != nil {
+		o.logger.CWarnw(ctx, "failed to read orientation sensor for obstacles depth; falling back to static ground normal", "error", err)
+		return defaultGroundNormal
+	}
+	normal := worldDownInCameraFrame(orientation, o.cameraToSensor)
+	o.logger.CDebugw(ctx, "obstacles depth computed ground normal from orientation sensor", "normal", normal)
+	return normal
+}
+
+// worldDownInCameraFrame rotates the world-down vector {0, -1, 0} into the camera's frame, given
+// the orientation sensor's reading (the sensor's orientation relative to the world frame) and the
+// static transform from the camera's frame to the sensor's frame. Only the rotational component
+// of cameraToSensor is used: translation is meaningless when rotating a direction vector, and
+// composing the full pose against the vector (treated as a point) would shift the result by
+// whatever offset cameraToSensor has from the sensor.
+func worldDownInCameraFrame(sensorOrientation spatialmath.Orientation, cameraToSensor spatialmath.Pose) r3.Vector {
+	sensorInWorld := spatialmath.NewPoseFromOrientation(sensorOrientation)
+	cameraToSensorRotationOnly := spatialmath.NewPoseFromOrientation(cameraToSensor.Orientation())
+	cameraInWorld := spatialmath.Compose(sensorInWorld, spatialmath.PoseInverse(cameraToSensorRotationOnly))
+	worldDown := spatialmath.NewPoseFromPoint(defaultGroundNormal)
+	rotated := spatialmath.Compose(spatialmath.PoseInverse(cameraInWorld), worldDown)
+	return rotated.Point().Normalize()
 }
 
 func (s *obsDepth) Name() resource.Name {
@@ -215,14 +489,68 @@ func (s *obsDepth) GetObjectPointClouds(ctx context.Context, cameraName string,
 	return segmenter(ctx, cam)
 }
 
+// GetProperties reports detection support based on whether camera intrinsics are available.
+// Intrinsics are normally only cached as a side effect of a prior GetObjectPointClouds call; if
+// none have been cached yet but a default camera is configured, its properties are queried
+// directly so the very first call reports accurate capabilities instead of a false negative.
 func (s *obsDepth) GetProperties(ctx context.Context, extra map[string]interface{}) (*vision.Properties, error) {
+	intrinsics := s.getIntrinsics()
+	if intrinsics == nil && s.defaultCamera != nil {
+		props, err := s.defaultCamera.Properties(ctx)
+		if err != nil {
+			s.logger.CWarnw(ctx, "failed to query default camera properties for obstacles depth", "error", err)
+		} else if props.IntrinsicParams != nil {
+			s.setIntrinsics(props.IntrinsicParams)
+			intrinsics = props.IntrinsicParams
+		}
+	}
 	return &vision.Properties{
 		ClassificationSupported: false,
-		DetectionSupported:      false,
+		DetectionSupported:      intrinsics != nil,
 		ObjectPCDsSupported:     true,
 	}, nil
 }
 
+// detectionsFromObjects projects each cluster's points back through the camera intrinsics to
+// find its 2D bounding box in pixel space. Points that lie behind the camera, or that project
+// outside the image bounds, are excluded from that box; a cluster with no points left after that
+// filtering is dropped entirely. Confidence is derived from how many points make up the cluster:
+// denser clusters are more likely to be real obstacles rather than depth noise.
+func detectionsFromObjects(objects []*vis.Object, intrinsics *transform.PinholeCameraIntrinsics) []objdet.Detection {
+	if intrinsics == nil {
+		return nil
+	}
+	dets := make([]objdet.Detection, 0, len(objects))
+	for _, obj := range objects {
+		if obj == nil || obj.PointCloud == nil {
+			continue
+		}
+		minX, minY := math.Inf(1), math.Inf(1)
+		maxX, maxY := math.Inf(-1), math.Inf(-1)
+		numPts := 0
+		obj.PointCloud.Iterate(0, 0, func(p r3.Vector, _ pointcloud.Data) bool {
+			if p.Z <= 0 {
+				return true
+			}
+			px, py := intrinsics.PointToPixel(p.X, p.Y, p.Z)
+			if px < 0 || py < 0 || px >= float64(intrinsics.Width) || py >= float64(intrinsics.Height) {
+				return true
+			}
+			minX, maxX = math.Min(minX, px), math.Max(maxX, px)
+			minY, maxY = math.Min(minY, py), math.Max(maxY, py)
+			numPts++
+			return true
+		})
+		if numPts == 0 {
+			continue
+		}
+		box := image.Rect(int(minX), int(minY), int(maxX)+1, int(maxY)+1)
+		confidence := math.Min(1.0, float64(numPts)/obstacleConfidenceDenom)
+		dets = append(dets, objdet.NewDetection(box, confidence, obstacleLabel))
+	}
+	return dets
+}
+
 func (s *obsDepth) CaptureAllFromCamera(ctx context.Context, cameraName string, captureOptions viscapture.CaptureOptions, extra map[string]interface{}) (viscapture.VisCapture, error) {
 	var cam camera.Camera
 	var err error
@@ -238,6 +566,20 @@ func (s *obsDepth) CaptureAllFromCamera(ctx context.Context, cameraName string,
 		return viscapture.VisCapture{}, errors.New("no camera specified")
 	}
 
+	captureFilter, _ := extra["capture_filter"].(bool)
+	var objects []*vis.Object
+	if captureFilter || captureOptions.ReturnObject || captureOptions.ReturnDetections {
+		objects, err = s.GetObjectPointClouds(ctx, cameraName, extra)
+		if err != nil {
+			return viscapture.VisCapture{}, err
+		}
+	}
+	if captureFilter && len(objects) == 0 {
+		// Nothing passed the ROI filter this frame: skip image encoding entirely so
+		// data-capture can drop the (empty) result instead of paying to store it.
+		return viscapture.VisCapture{}, nil
+	}
+
 	result := viscapture.VisCapture{}
 
 	if captureOptions.ReturnImage {
@@ -249,14 +591,17 @@ func (s *obsDepth) CaptureAllFromCamera(ctx context.Context, cameraName string,
 	}
 
 	if captureOptions.ReturnObject {
-		objects, err := s.GetObjectPointClouds(ctx, cameraName, extra)
-		if err != nil {
-			return viscapture.VisCapture{}, err
-		}
 		result.Objects = objects
 	}
 
-	result.Detections = []objdet.Detection{}
+	if captureOptions.ReturnDetections {
+		// Derive detections from the objects already computed above instead of calling
+		// DetectionsFromCamera, which would re-fetch the camera and re-run segmentation.
+		result.Detections = detectionsFromObjects(objects, s.getIntrinsics())
+	} else {
+		result.Detections = []objdet.Detection{}
+	}
+
 	result.Classifications = classification.Classifications{}
 
 	return result, nil
@@ -270,10 +615,30 @@ func (s *obsDepth) NewClientFromConn(ctx context.Context, conn rpc.ClientConn, r
 	return nil, errUnimplemented
 }
 func (s *obsDepth) DetectionsFromCamera(ctx context.Context, cameraName string, extra map[string]interface{}) ([]objdet.Detection, error) {
-	return nil, errUnimplemented
+	objects, err := s.GetObjectPointClouds(ctx, cameraName, extra)
+	if err != nil {
+		return nil, err
+	}
+	return detectionsFromObjects(objects, s.getIntrinsics()), nil
 }
 func (s *obsDepth) Detections(ctx context.Context, img image.Image, extra map[string]interface{}) ([]objdet.Detection, error) {
-	return nil, errUnimplemented
+	intrinsics := s.getIntrinsics()
+	if intrinsics == nil {
+		return nil, errors.New("obstacles depth has no camera intrinsics yet; call DetectionsFromCamera or GetObjectPointClouds at least once")
+	}
+	dm, err := rimage.ConvertImageToDepthMap(ctx, img)
+	if err != nil {
+		return nil, errors.New("could not convert image to depth map")
+	}
+	cloud := depthadapter.ToPointCloud(dm, intrinsics)
+	// Intentionally skips the temporal tracker: img may be an arbitrary externally supplied frame
+	// unrelated to the continuously polled camera stream the tracker's state is scoped to.
+	objects, err := s.segment(ctx, cloud)
+	if err != nil {
+		return nil, err
+	}
+	objects = filterByROI(objects, s.roi)
+	return detectionsFromObjects(objects, intrinsics), nil
 }
 func (s *obsDepth) ClassificationsFromCamera(ctx context.Context, cameraName string, n int, extra map[string]interface{}) (classification.Classifications, error) {
 	return nil, errUnimplemented
@@ -282,6 +647,190 @@ func (s *obsDepth) Classifications(ctx context.Context, img image.Image, n int,
 	return nil, errUnimplemented
 }
 
+// clusteringConfToMap converts an ErCCLConfig into the same key names accepted by the "set"
+// command, so that "get" and "set" stay in sync.
+func clusteringConfToMap(cfg *segmentation.ErCCLConfig) map[string]interface{} {
+	return map[string]interface{}{
+		"min_pts_in_plane":      cfg.MinPtsInPlane,
+		"min_pts_in_segment":    cfg.MinPtsInSegment,
+		"max_dist_from_plane":   cfg.MaxDistFromPlane,
+		"clustering_radius":     cfg.ClusteringRadius,
+		"clustering_strictness": cfg.ClusteringStrictness,
+		"angle_tolerance":       cfg.AngleTolerance,
+		"normal_vec": map[string]interface{}{
+			"x": cfg.NormalVec.X,
+			"y": cfg.NormalVec.Y,
+			"z": cfg.NormalVec.Z,
+		},
+	}
+}
+
+// applyClusteringSet mutates cfg in place from a "set" command payload, accepting any subset of
+// the fields produced by clusteringConfToMap.
+func applyClusteringSet(cfg *segmentation.ErCCLConfig, set map[string]interface{}) error {
+	asFloat := func(v interface{}) (float64, bool) {
+		f, ok := v.(float64)
+		return f, ok
+	}
+	if v, ok := set["min_pts_in_plane"]; ok {
+		f, ok := asFloat(v)
+		if !ok {
+			return errors.New("min_pts_in_plane must be a number")
+		}
+		cfg.MinPtsInPlane = int(f)
+	}
+	if v, ok := set["min_pts_in_segment"]; ok {
+		f, ok := asFloat(v)
+		if !ok {
+			return errors.New("min_pts_in_segment must be a number")
+		}
+		cfg.MinPtsInSegment = int(f)
+	}
+	if v, ok := set["max_dist_from_plane"]; ok {
+		f, ok := asFloat(v)
+		if !ok {
+			return errors.New("max_dist_from_plane must be a number")
+		}
+		cfg.MaxDistFromPlane = f
+	}
+	if v, ok := set["clustering_radius"]; ok {
+		f, ok := asFloat(v)
+		if !ok {
+			return errors.New("clustering_radius must be a number")
+		}
+		cfg.ClusteringRadius = int(f)
+	}
+	if v, ok := set["clustering_strictness"]; ok {
+		f, ok := asFloat(v)
+		if !ok {
+			return errors.New("clustering_strictness must be a number")
+		}
+		cfg.ClusteringStrictness = f
+	}
+	if v, ok := set["angle_tolerance"]; ok {
+		f, ok := asFloat(v)
+		if !ok {
+			return errors.New("angle_tolerance must be a number")
+		}
+		cfg.AngleTolerance = f
+	}
+	if v, ok := set["normal_vec"]; ok {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return errors.New("normal_vec must be an object with x, y, z")
+		}
+		x, xok := asFloat(m["x"])
+		y, yok := asFloat(m["y"])
+		z, zok := asFloat(m["z"])
+		if !xok || !yok || !zok {
+			return errors.New("normal_vec.x, normal_vec.y, and normal_vec.z must all be numbers")
+		}
+		cfg.NormalVec = r3.Vector{X: x, Y: y, Z: z}
+	}
+	return cfg.CheckValid()
+}
+
+// clusterStats summarizes a single cluster for diagnostic dumps: how many points it has, where
+// its centroid is, and the extent of its axis-aligned bounding box.
+type clusterStats struct {
+	NumPoints int       `json:"num_points"`
+	Centroid  r3.Vector `json:"centroid"`
+	AABBMin   r3.Vector `json:"aabb_min"`
+	AABBMax   r3.Vector `json:"aabb_max"`
+}
+
+func statsForObject(obj *vis.Object) clusterStats {
+	stats := clusterStats{
+		AABBMin: r3.Vector{X: math.Inf(1), Y: math.Inf(1), Z: math.Inf(1)},
+		AABBMax: r3.Vector{X: math.Inf(-1), Y: math.Inf(-1), Z: math.Inf(-1)},
+	}
+	if obj == nil || obj.PointCloud == nil {
+		return stats
+	}
+	var sum r3.Vector
+	obj.PointCloud.Iterate(0, 0, func(p r3.Vector, _ pointcloud.Data) bool {
+		stats.NumPoints++
+		sum = sum.Add(p)
+		stats.AABBMin.X, stats.AABBMax.X = math.Min(stats.AABBMin.X, p.X), math.Max(stats.AABBMax.X, p.X)
+		stats.AABBMin.Y, stats.AABBMax.Y = math.Min(stats.AABBMin.Y, p.Y), math.Max(stats.AABBMax.Y, p.Y)
+		stats.AABBMin.Z, stats.AABBMax.Z = math.Min(stats.AABBMin.Z, p.Z), math.Max(stats.AABBMax.Z, p.Z)
+		return true
+	})
+	if stats.NumPoints > 0 {
+		stats.Centroid = sum.Mul(1.0 / float64(stats.NumPoints))
+	}
+	return stats
+}
+
+// DoCommand supports three diagnostic/tuning operations, each keyed by its command name:
+//   - {"get": true} returns the current clustering config.
+//   - {"set": {...}} atomically updates any subset of the clustering config fields and returns
+//     the resulting config. A "normal_vec" set here is stored and reported by "get", but each
+//     segmentation call replaces it with the normal from computeNormalVec.
+//   - {"dump_pointcloud": true} returns the last projected point cloud, base64-encoded as a
+//     binary PCD, along with per-cluster stats from the last GetObjectPointClouds call.
 func (s *obsDepth) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
-	return nil, errUnimplemented
+	resp := map[string]interface{}{}
+
+	if set, ok := cmd["set"]; ok {
+		setMap, ok := set.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("\"set\" must be an object of clustering config fields")
+		}
+		s.confMu.Lock()
+		erccl, ok := s.segmenter.(*ercclSegmenter)
+		if !ok {
+			s.confMu.Unlock()
+			return nil, errors.New("\"set\" is only supported while algorithm is \"erccl\"")
+		}
+		confCopy := *erccl.conf
+		err := applyClusteringSet(&confCopy, setMap)
+		if err == nil {
+			*erccl.conf = confCopy
+		}
+		s.confMu.Unlock()
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid clustering config update")
+		}
+		resp["set"] = clusteringConfToMap(&confCopy)
+	}
+
+	if get, ok := cmd["get"]; ok && get == true {
+		s.confMu.Lock()
+		erccl, ok := s.segmenter.(*ercclSegmenter)
+		var confCopy segmentation.ErCCLConfig
+		if ok {
+			confCopy = *erccl.conf
+		}
+		s.confMu.Unlock()
+		if !ok {
+			return nil, errors.New("\"get\" is only supported while algorithm is \"erccl\"")
+		}
+		resp["get"] = clusteringConfToMap(&confCopy)
+	}
+
+	if dump, ok := cmd["dump_pointcloud"]; ok && dump == true {
+		s.lastMu.Lock()
+		cloud := s.lastCloud
+		objects := s.lastObject
+		s.lastMu.Unlock()
+
+		if cloud == nil {
+			return nil, errors.New("no point cloud has been captured yet; call GetObjectPointClouds first")
+		}
+		var buf bytes.Buffer
+		if err := pointcloud.ToPCD(cloud, &buf, pointcloud.PCDBinary); err != nil {
+			return nil, errors.Wrap(err, "failed to encode point cloud as PCD")
+		}
+		clusters := make([]clusterStats, len(objects))
+		for i, obj := range objects {
+			clusters[i] = statsForObject(obj)
+		}
+		resp["dump_pointcloud"] = map[string]interface{}{
+			"pcd_base64": base64.StdEncoding.EncodeToString(buf.Bytes()),
+			"clusters":   clusters,
+		}
+	}
+
+	return resp, nil
 }
@@ -0,0 +1,110 @@
+//go:build !no_cgo
+
+package obstaclesdepth
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+	vis "go.viam.com/rdk/vision"
+)
+
+func newTestIntrinsics() *transform.PinholeCameraIntrinsics {
+	return &transform.PinholeCameraIntrinsics{
+		Width:  100,
+		Height: 100,
+		Fx:     1,
+		Fy:     1,
+		Ppx:    50,
+		Ppy:    50,
+	}
+}
+
+func objectFromPoints(t *testing.T, points []r3.Vector) *vis.Object {
+	t.Helper()
+	pc := pointcloud.NewBasicEmpty()
+	for _, p := range points {
+		if err := pc.Set(p, nil); err != nil {
+			t.Fatalf("failed to build test point cloud: %v", err)
+		}
+	}
+	obj, err := vis.NewObject(pc)
+	if err != nil {
+		t.Fatalf("failed to build test object: %v", err)
+	}
+	return obj
+}
+
+func TestDetectionsFromObjectsNilIntrinsics(t *testing.T) {
+	obj := objectFromPoints(t, []r3.Vector{{X: 0, Y: 0, Z: 10}})
+	if got := detectionsFromObjects([]*vis.Object{obj}, nil); got != nil {
+		t.Errorf("expected nil detections with nil intrinsics, got %v", got)
+	}
+}
+
+func TestDetectionsFromObjectsDropsBehindCamera(t *testing.T) {
+	intrinsics := newTestIntrinsics()
+	obj := objectFromPoints(t, []r3.Vector{{X: 0, Y: 0, Z: -10}})
+	dets := detectionsFromObjects([]*vis.Object{obj}, intrinsics)
+	if len(dets) != 0 {
+		t.Errorf("expected no detections for a cluster entirely behind the camera, got %v", dets)
+	}
+}
+
+func TestDetectionsFromObjectsDropsOutOfImageBounds(t *testing.T) {
+	intrinsics := newTestIntrinsics()
+	// Projects to (50 + 1000/10, 50) = (150, 50), well outside the 100x100 image.
+	obj := objectFromPoints(t, []r3.Vector{{X: 1000, Y: 0, Z: 10}})
+	dets := detectionsFromObjects([]*vis.Object{obj}, intrinsics)
+	if len(dets) != 0 {
+		t.Errorf("expected no detections for a cluster that projects outside the image, got %v", dets)
+	}
+}
+
+func TestDetectionsFromObjectsInBounds(t *testing.T) {
+	intrinsics := newTestIntrinsics()
+	// Projects to (50, 50), the image center.
+	obj := objectFromPoints(t, []r3.Vector{{X: 0, Y: 0, Z: 10}})
+	dets := detectionsFromObjects([]*vis.Object{obj}, intrinsics)
+	if len(dets) != 1 {
+		t.Fatalf("expected 1 detection, got %d: %v", len(dets), dets)
+	}
+	if dets[0].Label() != obstacleLabel {
+		t.Errorf("expected label %q, got %q", obstacleLabel, dets[0].Label())
+	}
+}
+
+func TestDetectionsFromObjectsMixedInAndOutOfBoundsPoints(t *testing.T) {
+	intrinsics := newTestIntrinsics()
+	// One point projects in-bounds at (50, 50), the other well outside the image.
+	obj := objectFromPoints(t, []r3.Vector{
+		{X: 0, Y: 0, Z: 10},
+		{X: 1000, Y: 0, Z: 10},
+	})
+	dets := detectionsFromObjects([]*vis.Object{obj}, intrinsics)
+	if len(dets) != 1 {
+		t.Fatalf("expected 1 detection built from only the in-bounds point, got %d: %v", len(dets), dets)
+	}
+	box := dets[0].BoundingBox()
+	if box.Min.X != 50 || box.Min.Y != 50 {
+		t.Errorf("expected box anchored at the single in-bounds point (50, 50), got %v", box)
+	}
+}
+
+func TestWorldDownInCameraFrameIgnoresCameraToSensorTranslation(t *testing.T) {
+	// A camera/sensor that are coaxial (identity orientation) but offset from one another should
+	// still report straight down as the ground normal: translation must not leak into a rotation.
+	sensorOrientation := spatialmath.NewZeroOrientation()
+	offsetOnly := spatialmath.NewPoseFromPoint(r3.Vector{X: 100, Y: 200, Z: 300})
+	got := worldDownInCameraFrame(sensorOrientation, offsetOnly)
+	want := defaultGroundNormal
+	const tol = 1e-9
+	if math.Abs(got.X-want.X) > tol || math.Abs(got.Y-want.Y) > tol || math.Abs(got.Z-want.Z) > tol {
+		t.Errorf("worldDownInCameraFrame() = %v, want %v (translation leaked into the rotation)", got, want)
+	}
+}
@@ -0,0 +1,63 @@
+//go:build !no_cgo
+
+package obstaclesdepth
+
+import (
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	vis "go.viam.com/rdk/vision"
+)
+
+// ROIConfig restricts GetObjectPointClouds and Detections (and everything built on top of them,
+// including CaptureAllFromCamera's capture-filter fast path) to obstacles whose centroid lies
+// inside a box in the camera frame, in millimeters. When the camera has no intrinsics,
+// GetObjectPointClouds returns its median-depth point without applying the ROI.
+type ROIConfig struct {
+	MinXMm float64 `json:"min_x_mm"`
+	MaxXMm float64 `json:"max_x_mm"`
+	MinYMm float64 `json:"min_y_mm"`
+	MaxYMm float64 `json:"max_y_mm"`
+	MinZMm float64 `json:"min_z_mm"`
+	MaxZMm float64 `json:"max_z_mm"`
+}
+
+// CheckValid returns an error describing the first invalid field, or nil if c is usable.
+func (c *ROIConfig) CheckValid() error {
+	if c.MinXMm > c.MaxXMm {
+		return errors.New("roi.min_x_mm must be <= roi.max_x_mm")
+	}
+	if c.MinYMm > c.MaxYMm {
+		return errors.New("roi.min_y_mm must be <= roi.max_y_mm")
+	}
+	if c.MinZMm > c.MaxZMm {
+		return errors.New("roi.min_z_mm must be <= roi.max_z_mm")
+	}
+	return nil
+}
+
+// Contains reports whether p falls inside the ROI box.
+func (c *ROIConfig) Contains(p r3.Vector) bool {
+	return p.X >= c.MinXMm && p.X <= c.MaxXMm &&
+		p.Y >= c.MinYMm && p.Y <= c.MaxYMm &&
+		p.Z >= c.MinZMm && p.Z <= c.MaxZMm
+}
+
+// filterByROI drops every object whose point cloud centroid falls outside roi. A nil roi passes
+// everything through unfiltered.
+func filterByROI(objects []*vis.Object, roi *ROIConfig) []*vis.Object {
+	if roi == nil {
+		return objects
+	}
+	filtered := make([]*vis.Object, 0, len(objects))
+	for _, obj := range objects {
+		stats := statsForObject(obj)
+		if stats.NumPoints == 0 {
+			continue
+		}
+		if roi.Contains(stats.Centroid) {
+			filtered = append(filtered, obj)
+		}
+	}
+	return filtered
+}
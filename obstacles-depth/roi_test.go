@@ -0,0 +1,108 @@
+//go:build !no_cgo
+
+package obstaclesdepth
+
+import (
+	"testing"
+
+	"github.com/golang/geo/r3"
+
+	"go.viam.com/rdk/pointcloud"
+	vis "go.viam.com/rdk/vision"
+)
+
+func TestROIConfigCheckValid(t *testing.T) {
+	cases := []struct {
+		name    string
+		conf    ROIConfig
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			conf: ROIConfig{MinXMm: -10, MaxXMm: 10, MinYMm: -10, MaxYMm: 10, MinZMm: 0, MaxZMm: 1000},
+		},
+		{
+			name:    "min x above max x",
+			conf:    ROIConfig{MinXMm: 10, MaxXMm: -10, MinYMm: -10, MaxYMm: 10, MinZMm: 0, MaxZMm: 1000},
+			wantErr: true,
+		},
+		{
+			name:    "min y above max y",
+			conf:    ROIConfig{MinXMm: -10, MaxXMm: 10, MinYMm: 10, MaxYMm: -10, MinZMm: 0, MaxZMm: 1000},
+			wantErr: true,
+		},
+		{
+			name:    "min z above max z",
+			conf:    ROIConfig{MinXMm: -10, MaxXMm: 10, MinYMm: -10, MaxYMm: 10, MinZMm: 1000, MaxZMm: 0},
+			wantErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.conf.CheckValid()
+			if (err != nil) != c.wantErr {
+				t.Errorf("CheckValid() error = %v, wantErr %v", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestROIConfigContains(t *testing.T) {
+	roi := &ROIConfig{MinXMm: -10, MaxXMm: 10, MinYMm: -10, MaxYMm: 10, MinZMm: 0, MaxZMm: 1000}
+	cases := []struct {
+		name string
+		p    r3.Vector
+		want bool
+	}{
+		{"inside", r3.Vector{X: 0, Y: 0, Z: 500}, true},
+		{"on boundary", r3.Vector{X: 10, Y: 10, Z: 1000}, true},
+		{"outside x", r3.Vector{X: 11, Y: 0, Z: 500}, false},
+		{"outside y", r3.Vector{X: 0, Y: -11, Z: 500}, false},
+		{"outside z", r3.Vector{X: 0, Y: 0, Z: -1}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := roi.Contains(c.p); got != c.want {
+				t.Errorf("Contains(%v) = %v, want %v", c.p, got, c.want)
+			}
+		})
+	}
+}
+
+func TestFilterByROINilPassesThrough(t *testing.T) {
+	objects := make([]*vis.Object, 3)
+	if got := filterByROI(objects, nil); len(got) != len(objects) {
+		t.Errorf("filterByROI with nil roi returned %d objects, want %d", len(got), len(objects))
+	}
+}
+
+func TestFilterByROIDropsOutOfBoundsAndEmpty(t *testing.T) {
+	roi := &ROIConfig{MinXMm: -10, MaxXMm: 10, MinYMm: -10, MaxYMm: 10, MinZMm: 0, MaxZMm: 1000}
+
+	inBounds := pointcloud.NewBasicEmpty()
+	if err := inBounds.Set(r3.Vector{X: 0, Y: 0, Z: 500}, nil); err != nil {
+		t.Fatalf("failed to build test point cloud: %v", err)
+	}
+	inBoundsObj, err := vis.NewObject(inBounds)
+	if err != nil {
+		t.Fatalf("failed to build test object: %v", err)
+	}
+
+	outOfBounds := pointcloud.NewBasicEmpty()
+	if err := outOfBounds.Set(r3.Vector{X: 500, Y: 500, Z: 500}, nil); err != nil {
+		t.Fatalf("failed to build test point cloud: %v", err)
+	}
+	outOfBoundsObj, err := vis.NewObject(outOfBounds)
+	if err != nil {
+		t.Fatalf("failed to build test object: %v", err)
+	}
+
+	objects := []*vis.Object{inBoundsObj, outOfBoundsObj, nil, {PointCloud: nil}}
+	got := filterByROI(objects, roi)
+	if len(got) != 1 {
+		t.Fatalf("expected 1 object to pass the ROI filter, got %d", len(got))
+	}
+	if got[0] != inBoundsObj {
+		t.Errorf("expected the in-bounds object to survive filtering")
+	}
+}
@@ -0,0 +1,309 @@
+//go:build !no_cgo
+
+package obstaclesdepth
+
+import (
+	"context"
+	"math"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/pointcloud"
+	vis "go.viam.com/rdk/vision"
+	"go.viam.com/rdk/vision/segmentation"
+)
+
+// Segmenter clusters a point cloud already projected into camera frame into discrete obstacle
+// objects. ObsDepthConfig.Algorithm selects which implementation registerObstaclesDepth wires up.
+type Segmenter interface {
+	Segment(ctx context.Context, cloud pointcloud.PointCloud) ([]*vis.Object, error)
+}
+
+// ercclSegmenter is the default backend, delegating to the existing ERCCL library call.
+type ercclSegmenter struct {
+	conf *segmentation.ErCCLConfig
+}
+
+func (e *ercclSegmenter) Segment(ctx context.Context, cloud pointcloud.PointCloud) ([]*vis.Object, error) {
+	return segmentation.ApplyERCCLToPointCloud(ctx, cloud, e.conf)
+}
+
+// RadiusClusteringConfig configures the "radius" segmentation backend: points within
+// ClusteringRadiusMm of one another are grouped by connected components, and groups smaller than
+// MinPtsInSegment are dropped as noise. This is the cheapest of the non-ERCCL backends since it
+// does no ground-plane fitting.
+type RadiusClusteringConfig struct {
+	ClusteringRadiusMm float64 `json:"clustering_radius_mm"`
+	MinPtsInSegment    int     `json:"min_points_in_segment"`
+}
+
+// CheckValid returns an error describing the first invalid field, or nil if c is usable.
+func (c *RadiusClusteringConfig) CheckValid() error {
+	if c.ClusteringRadiusMm <= 0 {
+		return errors.New("radius.clustering_radius_mm must be positive")
+	}
+	if c.MinPtsInSegment <= 0 {
+		return errors.New("radius.min_points_in_segment must be positive")
+	}
+	return nil
+}
+
+type radiusSegmenter struct {
+	conf *RadiusClusteringConfig
+}
+
+func (r *radiusSegmenter) Segment(ctx context.Context, cloud pointcloud.PointCloud) ([]*vis.Object, error) {
+	points, data := pointsFromCloud(cloud)
+	clusters := connectedComponents(points, r.conf.ClusteringRadiusMm)
+	return objectsFromClusters(points, data, clusters, r.conf.MinPtsInSegment, 0)
+}
+
+// EuclideanClusteringConfig configures the "euclidean" segmentation backend, modeled on PCL's
+// Euclidean cluster extraction: points connect at ClusterToleranceMm, and clusters outside
+// [MinClusterSize, MaxClusterSize] are discarded. MaxClusterSize of 0 means unbounded.
+type EuclideanClusteringConfig struct {
+	ClusterToleranceMm float64 `json:"cluster_tolerance_mm"`
+	MinClusterSize     int     `json:"min_cluster_size"`
+	MaxClusterSize     int     `json:"max_cluster_size"`
+}
+
+// CheckValid returns an error describing the first invalid field, or nil if c is usable.
+func (c *EuclideanClusteringConfig) CheckValid() error {
+	if c.ClusterToleranceMm <= 0 {
+		return errors.New("euclidean.cluster_tolerance_mm must be positive")
+	}
+	if c.MinClusterSize <= 0 {
+		return errors.New("euclidean.min_cluster_size must be positive")
+	}
+	if c.MaxClusterSize != 0 && c.MaxClusterSize < c.MinClusterSize {
+		return errors.New("euclidean.max_cluster_size must be 0 (unbounded) or >= min_cluster_size")
+	}
+	return nil
+}
+
+type euclideanSegmenter struct {
+	conf *EuclideanClusteringConfig
+}
+
+func (e *euclideanSegmenter) Segment(ctx context.Context, cloud pointcloud.PointCloud) ([]*vis.Object, error) {
+	points, data := pointsFromCloud(cloud)
+	clusters := connectedComponents(points, e.conf.ClusterToleranceMm)
+	return objectsFromClusters(points, data, clusters, e.conf.MinClusterSize, e.conf.MaxClusterSize)
+}
+
+// DBSCANConfig configures the "dbscan" segmentation backend: a point is a core point when at
+// least MinPts points, counting the point itself, lie within EpsMm of it, and core points within EpsMm of one another
+// share a cluster. Points reachable from no core point are treated as noise and dropped. This is
+// the best fit for scenes with uneven point density, where a single radius threshold either
+// merges sparse obstacles or fragments dense ones.
+type DBSCANConfig struct {
+	EpsMm  float64 `json:"eps_mm"`
+	MinPts int     `json:"min_pts"`
+}
+
+// CheckValid returns an error describing the first invalid field, or nil if c is usable.
+func (c *DBSCANConfig) CheckValid() error {
+	if c.EpsMm <= 0 {
+		return errors.New("dbscan.eps_mm must be positive")
+	}
+	if c.MinPts <= 0 {
+		return errors.New("dbscan.min_pts must be positive")
+	}
+	return nil
+}
+
+type dbscanSegmenter struct {
+	conf *DBSCANConfig
+}
+
+func (d *dbscanSegmenter) Segment(ctx context.Context, cloud pointcloud.PointCloud) ([]*vis.Object, error) {
+	points, data := pointsFromCloud(cloud)
+	clusters := dbscanClusters(points, d.conf.EpsMm, d.conf.MinPts)
+	return objectsFromClusters(points, data, clusters, 1, 0)
+}
+
+// pointsFromCloud flattens a pointcloud.PointCloud into parallel slices so the clustering
+// algorithms below can do repeated index-based neighbor lookups instead of re-iterating the
+// point cloud for every query.
+func pointsFromCloud(cloud pointcloud.PointCloud) ([]r3.Vector, []pointcloud.Data) {
+	points := make([]r3.Vector, 0, cloud.Size())
+	data := make([]pointcloud.Data, 0, cloud.Size())
+	cloud.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		points = append(points, p)
+		data = append(data, d)
+		return true
+	})
+	return points, data
+}
+
+// objectsFromClusters builds one vis.Object per cluster whose size falls within
+// [minSize, maxSize], where maxSize of 0 means unbounded.
+func objectsFromClusters(points []r3.Vector, data []pointcloud.Data, clusters [][]int, minSize, maxSize int) ([]*vis.Object, error) {
+	objects := make([]*vis.Object, 0, len(clusters))
+	for _, idxs := range clusters {
+		if len(idxs) < minSize || (maxSize != 0 && len(idxs) > maxSize) {
+			continue
+		}
+		pc := pointcloud.NewBasicEmpty()
+		for _, idx := range idxs {
+			if err := pc.Set(points[idx], data[idx]); err != nil {
+				return nil, errors.Wrap(err, "failed to build cluster point cloud")
+			}
+		}
+		obj, err := vis.NewObject(pc)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to build obstacle from cluster")
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// spatialGrid buckets points into cubic cells so that neighbor queries at a fixed radius only
+// need to visit the 27 cells surrounding a point rather than the whole cloud.
+type spatialGrid struct {
+	cellSize float64
+	points   []r3.Vector
+	cells    map[[3]int][]int
+}
+
+func newSpatialGrid(points []r3.Vector, cellSize float64) *spatialGrid {
+	g := &spatialGrid{cellSize: cellSize, points: points, cells: make(map[[3]int][]int)}
+	for i, p := range points {
+		key := g.cellKey(p)
+		g.cells[key] = append(g.cells[key], i)
+	}
+	return g
+}
+
+func (g *spatialGrid) cellKey(p r3.Vector) [3]int {
+	return [3]int{
+		int(math.Floor(p.X / g.cellSize)),
+		int(math.Floor(p.Y / g.cellSize)),
+		int(math.Floor(p.Z / g.cellSize)),
+	}
+}
+
+// neighbors returns the indices of every point within radius of points[i], excluding i itself.
+func (g *spatialGrid) neighbors(i int, radius float64) []int {
+	p := g.points[i]
+	base := g.cellKey(p)
+	var out []int
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			for dz := -1; dz <= 1; dz++ {
+				key := [3]int{base[0] + dx, base[1] + dy, base[2] + dz}
+				for _, j := range g.cells[key] {
+					if j == i {
+						continue
+					}
+					if p.Sub(g.points[j]).Norm() <= radius {
+						out = append(out, j)
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// unionFind is a standard disjoint-set structure used to group points into connected components.
+type unionFind struct {
+	parent []int
+}
+
+func newUnionFind(n int) *unionFind {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	return &unionFind{parent: parent}
+}
+
+func (u *unionFind) find(x int) int {
+	for u.parent[x] != x {
+		u.parent[x] = u.parent[u.parent[x]]
+		x = u.parent[x]
+	}
+	return x
+}
+
+func (u *unionFind) union(x, y int) {
+	rx, ry := u.find(x), u.find(y)
+	if rx != ry {
+		u.parent[rx] = ry
+	}
+}
+
+// connectedComponents groups points into clusters by transitive closure of "within radius of
+// each other". It underlies both the "radius" and "euclidean" backends, which differ only in how
+// they size-filter the resulting clusters.
+func connectedComponents(points []r3.Vector, radius float64) [][]int {
+	if len(points) == 0 {
+		return nil
+	}
+	grid := newSpatialGrid(points, radius)
+	uf := newUnionFind(len(points))
+	for i := range points {
+		for _, j := range grid.neighbors(i, radius) {
+			uf.union(i, j)
+		}
+	}
+	groups := make(map[int][]int)
+	for i := range points {
+		root := uf.find(i)
+		groups[root] = append(groups[root], i)
+	}
+	clusters := make([][]int, 0, len(groups))
+	for _, idxs := range groups {
+		clusters = append(clusters, idxs)
+	}
+	return clusters
+}
+
+// dbscanClusters implements the classic DBSCAN algorithm: points are grown outward from core
+// points (those with at least minPts points within eps, counting the point itself) into clusters, and any point never
+// reached from a core point is left out of the returned clusters as noise.
+func dbscanClusters(points []r3.Vector, eps float64, minPts int) [][]int {
+	if len(points) == 0 {
+		return nil
+	}
+	grid := newSpatialGrid(points, eps)
+	visited := make([]bool, len(points))
+	inCluster := make([]bool, len(points))
+	var clusters [][]int
+
+	for i := range points {
+		if visited[i] {
+			continue
+		}
+		visited[i] = true
+		neighbors := grid.neighbors(i, eps)
+		if len(neighbors)+1 < minPts {
+			continue
+		}
+
+		cluster := []int{i}
+		inCluster[i] = true
+		seeds := append([]int{}, neighbors...)
+		for len(seeds) > 0 {
+			j := seeds[0]
+			seeds = seeds[1:]
+			if !inCluster[j] {
+				inCluster[j] = true
+				cluster = append(cluster, j)
+			}
+			if visited[j] {
+				continue
+			}
+			visited[j] = true
+			jNeighbors := grid.neighbors(j, eps)
+			if len(jNeighbors)+1 >= minPts {
+				seeds = append(seeds, jNeighbors...)
+			}
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters
+}
@@ -0,0 +1,134 @@
+//go:build !no_cgo
+
+package obstaclesdepth
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/golang/geo/r3"
+)
+
+func sortedClusters(clusters [][]int) [][]int {
+	out := make([][]int, len(clusters))
+	for i, c := range clusters {
+		cp := append([]int{}, c...)
+		sort.Ints(cp)
+		out[i] = cp
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i][0] < out[j][0] })
+	return out
+}
+
+func TestConnectedComponents(t *testing.T) {
+	points := []r3.Vector{
+		{X: 0, Y: 0, Z: 0},
+		{X: 1, Y: 0, Z: 0},
+		{X: 2, Y: 0, Z: 0},
+		{X: 100, Y: 0, Z: 0},
+	}
+	clusters := sortedClusters(connectedComponents(points, 1.5))
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %d: %v", len(clusters), clusters)
+	}
+	if len(clusters[0]) != 3 {
+		t.Errorf("expected first cluster to have 3 points, got %v", clusters[0])
+	}
+	if len(clusters[1]) != 1 {
+		t.Errorf("expected second cluster to have 1 point, got %v", clusters[1])
+	}
+}
+
+func TestConnectedComponentsEmpty(t *testing.T) {
+	if clusters := connectedComponents(nil, 1); clusters != nil {
+		t.Errorf("expected nil clusters for empty input, got %v", clusters)
+	}
+}
+
+func TestDBSCANClustersDropsNoise(t *testing.T) {
+	points := []r3.Vector{
+		{X: 0, Y: 0, Z: 0},
+		{X: 1, Y: 0, Z: 0},
+		{X: 2, Y: 0, Z: 0},
+		{X: 50, Y: 0, Z: 0}, // isolated noise point, no neighbors within eps
+	}
+	clusters := dbscanClusters(points, 1.5, 3)
+	if len(clusters) != 1 {
+		t.Fatalf("expected 1 cluster, got %d: %v", len(clusters), clusters)
+	}
+	if len(clusters[0]) != 3 {
+		t.Errorf("expected cluster to have 3 points, got %v", clusters[0])
+	}
+}
+
+func TestDBSCANClustersAllNoiseWhenMinPtsUnmet(t *testing.T) {
+	points := []r3.Vector{
+		{X: 0, Y: 0, Z: 0},
+		{X: 1, Y: 0, Z: 0},
+	}
+	clusters := dbscanClusters(points, 1.5, 5)
+	if len(clusters) != 0 {
+		t.Errorf("expected no clusters, got %v", clusters)
+	}
+}
+
+func TestRadiusClusteringConfigCheckValid(t *testing.T) {
+	cases := []struct {
+		name    string
+		conf    RadiusClusteringConfig
+		wantErr bool
+	}{
+		{"valid", RadiusClusteringConfig{ClusteringRadiusMm: 10, MinPtsInSegment: 5}, false},
+		{"zero radius", RadiusClusteringConfig{ClusteringRadiusMm: 0, MinPtsInSegment: 5}, true},
+		{"zero min points", RadiusClusteringConfig{ClusteringRadiusMm: 10, MinPtsInSegment: 0}, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.conf.CheckValid()
+			if (err != nil) != c.wantErr {
+				t.Errorf("CheckValid() error = %v, wantErr %v", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestEuclideanClusteringConfigCheckValid(t *testing.T) {
+	cases := []struct {
+		name    string
+		conf    EuclideanClusteringConfig
+		wantErr bool
+	}{
+		{"valid unbounded", EuclideanClusteringConfig{ClusterToleranceMm: 10, MinClusterSize: 5, MaxClusterSize: 0}, false},
+		{"valid bounded", EuclideanClusteringConfig{ClusterToleranceMm: 10, MinClusterSize: 5, MaxClusterSize: 50}, false},
+		{"max below min", EuclideanClusteringConfig{ClusterToleranceMm: 10, MinClusterSize: 50, MaxClusterSize: 5}, true},
+		{"zero tolerance", EuclideanClusteringConfig{ClusterToleranceMm: 0, MinClusterSize: 5}, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.conf.CheckValid()
+			if (err != nil) != c.wantErr {
+				t.Errorf("CheckValid() error = %v, wantErr %v", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestDBSCANConfigCheckValid(t *testing.T) {
+	cases := []struct {
+		name    string
+		conf    DBSCANConfig
+		wantErr bool
+	}{
+		{"valid", DBSCANConfig{EpsMm: 10, MinPts: 3}, false},
+		{"zero eps", DBSCANConfig{EpsMm: 0, MinPts: 3}, true},
+		{"zero min pts", DBSCANConfig{EpsMm: 10, MinPts: 0}, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.conf.CheckValid()
+			if (err != nil) != c.wantErr {
+				t.Errorf("CheckValid() error = %v, wantErr %v", err, c.wantErr)
+			}
+		})
+	}
+}
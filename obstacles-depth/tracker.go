@@ -0,0 +1,292 @@
+//go:build !no_cgo
+
+package obstaclesdepth
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/spatialmath"
+	vis "go.viam.com/rdk/vision"
+)
+
+// trackSmoothingAlpha weights a track's new measurement against its prior smoothed geometry on
+// every matched frame: 1.0 would track the raw measurement exactly (no smoothing), 0.0 would
+// never update. 0.6 favors the new measurement while still damping single-frame noise.
+const trackSmoothingAlpha = 0.6
+
+// TrackingConfig enables a temporal tracking layer on top of the per-frame segmentation result:
+// clusters are matched to tracks from previous calls so downstream consumers (e.g. motion
+// planning) see stable obstacle identities and smoothed geometry instead of an uncorrelated,
+// flickering set of objects every frame.
+type TrackingConfig struct {
+	Enabled      bool    `json:"enabled"`
+	MaxAgeMs     int     `json:"max_age_ms"`
+	IoUThreshold float64 `json:"iou_threshold"`
+}
+
+// CheckValid returns an error describing the first invalid field, or nil if c is usable.
+func (c *TrackingConfig) CheckValid() error {
+	if c.MaxAgeMs <= 0 {
+		return errors.New("tracking.max_age_ms must be positive")
+	}
+	if c.IoUThreshold <= 0 || c.IoUThreshold > 1 {
+		return errors.New("tracking.iou_threshold must be in (0, 1]")
+	}
+	return nil
+}
+
+func (c *TrackingConfig) maxAge() time.Duration {
+	return time.Duration(c.MaxAgeMs) * time.Millisecond
+}
+
+// track is the tracker's persistent state for a single obstacle, keyed by its stable ID.
+type track struct {
+	lastSeen  time.Time
+	smoothMin r3.Vector
+	smoothMax r3.Vector
+}
+
+// tracker matches each frame's clusters to the previous frames' tracks, assigning stable IDs and
+// EMA-smoothing their geometry. It is safe for concurrent use.
+type tracker struct {
+	mu     sync.Mutex
+	tracks map[string]*track
+	nextID int
+}
+
+func newTracker() *tracker {
+	return &tracker{tracks: make(map[string]*track)}
+}
+
+func (t *tracker) newTrackID() string {
+	t.nextID++
+	return fmt.Sprintf("track-%d", t.nextID)
+}
+
+// Update matches this frame's objects against live tracks by 3D IoU under a Hungarian assignment,
+// spawns new tracks for unmatched objects, drops tracks not matched within maxAge, and returns
+// objects whose Geometry is replaced by the track's EMA-smoothed bounding box labeled with its
+// stable track ID.
+func (t *tracker) Update(objects []*vis.Object, now time.Time, maxAge time.Duration, iouThreshold float64) []*vis.Object {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	type aabb struct {
+		min, max r3.Vector
+	}
+	boxes := make([]aabb, len(objects))
+	for i, obj := range objects {
+		min, max := aabbFromObject(obj)
+		boxes[i] = aabb{min, max}
+	}
+
+	ids := make([]string, 0, len(t.tracks))
+	for id := range t.tracks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	cost := make([][]float64, len(ids))
+	for i, id := range ids {
+		tr := t.tracks[id]
+		cost[i] = make([]float64, len(boxes))
+		for j, b := range boxes {
+			cost[i][j] = 1 - iou3D(tr.smoothMin, tr.smoothMax, b.min, b.max)
+		}
+	}
+	assignment := solveAssignment(cost)
+
+	matched := make([]bool, len(boxes))
+	result := make([]*vis.Object, len(boxes))
+
+	for i, id := range ids {
+		j := assignment[i]
+		if j < 0 {
+			continue
+		}
+		tr := t.tracks[id]
+		if iou3D(tr.smoothMin, tr.smoothMax, boxes[j].min, boxes[j].max) < iouThreshold {
+			continue
+		}
+		tr.lastSeen = now
+		tr.smoothMin = lerp(tr.smoothMin, boxes[j].min, trackSmoothingAlpha)
+		tr.smoothMax = lerp(tr.smoothMax, boxes[j].max, trackSmoothingAlpha)
+		matched[j] = true
+		result[j] = labeledObject(objects[j], id, tr.smoothMin, tr.smoothMax)
+	}
+
+	for j, obj := range objects {
+		if matched[j] {
+			continue
+		}
+		id := t.newTrackID()
+		t.tracks[id] = &track{lastSeen: now, smoothMin: boxes[j].min, smoothMax: boxes[j].max}
+		result[j] = labeledObject(obj, id, boxes[j].min, boxes[j].max)
+	}
+
+	for id, tr := range t.tracks {
+		if now.Sub(tr.lastSeen) > maxAge {
+			delete(t.tracks, id)
+		}
+	}
+
+	return result
+}
+
+func lerp(a, b r3.Vector, alpha float64) r3.Vector {
+	return a.Mul(1 - alpha).Add(b.Mul(alpha))
+}
+
+// aabbFromObject returns the axis-aligned bounding box of obj's point cloud.
+func aabbFromObject(obj *vis.Object) (r3.Vector, r3.Vector) {
+	stats := statsForObject(obj)
+	return stats.AABBMin, stats.AABBMax
+}
+
+// labeledObject returns a copy of obj whose Geometry is the [min, max] box labeled with id,
+// keeping obj's original point cloud. minBoxDimMm guards against the degenerate zero-volume boxes
+// that a single-point cluster would otherwise produce.
+func labeledObject(obj *vis.Object, id string, min, max r3.Vector) *vis.Object {
+	const minBoxDimMm = 1.0
+	dims := max.Sub(min)
+	dims.X = math.Max(dims.X, minBoxDimMm)
+	dims.Y = math.Max(dims.Y, minBoxDimMm)
+	dims.Z = math.Max(dims.Z, minBoxDimMm)
+	mid := min.Add(max).Mul(0.5)
+
+	box, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(mid), dims, id)
+	if err != nil {
+		return obj
+	}
+	return &vis.Object{PointCloud: obj.PointCloud, Geometry: box}
+}
+
+// iou3D computes the intersection-over-union of two axis-aligned boxes.
+func iou3D(minA, maxA, minB, maxB r3.Vector) float64 {
+	interMin := r3.Vector{X: math.Max(minA.X, minB.X), Y: math.Max(minA.Y, minB.Y), Z: math.Max(minA.Z, minB.Z)}
+	interMax := r3.Vector{X: math.Min(maxA.X, maxB.X), Y: math.Min(maxA.Y, maxB.Y), Z: math.Min(maxA.Z, maxB.Z)}
+	interVol := boxVolume(interMin, interMax)
+	if interVol <= 0 {
+		return 0
+	}
+	union := boxVolume(minA, maxA) + boxVolume(minB, maxB) - interVol
+	if union <= 0 {
+		return 0
+	}
+	return interVol / union
+}
+
+func boxVolume(min, max r3.Vector) float64 {
+	dx, dy, dz := max.X-min.X, max.Y-min.Y, max.Z-min.Z
+	if dx <= 0 || dy <= 0 || dz <= 0 {
+		return 0
+	}
+	return dx * dy * dz
+}
+
+// solveAssignment finds a minimum-cost assignment between rows and columns of a (possibly
+// rectangular) cost matrix using the Hungarian algorithm, internally padding to square with a
+// sentinel cost so extra rows or columns go unmatched. assignment[i] is the column matched to row
+// i, or -1 if row i has no real (non-sentinel) match. Returns nil for an empty matrix.
+func solveAssignment(cost [][]float64) []int {
+	numRows := len(cost)
+	if numRows == 0 {
+		return nil
+	}
+	numCols := len(cost[0])
+	n := numRows
+	if numCols > n {
+		n = numCols
+	}
+
+	const sentinel = 1e6
+	a := make([][]float64, n+1)
+	for i := range a {
+		a[i] = make([]float64, n+1)
+	}
+	for i := 1; i <= numRows; i++ {
+		for j := 1; j <= numCols; j++ {
+			a[i][j] = cost[i-1][j-1]
+		}
+		for j := numCols + 1; j <= n; j++ {
+			a[i][j] = sentinel
+		}
+	}
+	for i := numRows + 1; i <= n; i++ {
+		for j := 1; j <= n; j++ {
+			a[i][j] = sentinel
+		}
+	}
+
+	const inf = math.MaxFloat64 / 2
+	u := make([]float64, n+1)
+	v := make([]float64, n+1)
+	p := make([]int, n+1) // p[j] = row currently assigned to column j
+	way := make([]int, n+1)
+
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		minV := make([]float64, n+1)
+		used := make([]bool, n+1)
+		for j := range minV {
+			minV[j] = inf
+		}
+		for {
+			used[j0] = true
+			i0 := p[j0]
+			delta := inf
+			j1 := 0
+			for j := 1; j <= n; j++ {
+				if used[j] {
+					continue
+				}
+				cur := a[i0][j] - u[i0] - v[j]
+				if cur < minV[j] {
+					minV[j] = cur
+					way[j] = j0
+				}
+				if minV[j] < delta {
+					delta = minV[j]
+					j1 = j
+				}
+			}
+			for j := 0; j <= n; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minV[j] -= delta
+				}
+			}
+			j0 = j1
+			if p[j0] == 0 {
+				break
+			}
+		}
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+
+	assignment := make([]int, numRows)
+	for i := range assignment {
+		assignment[i] = -1
+	}
+	for j := 1; j <= n; j++ {
+		i := p[j]
+		if i >= 1 && i <= numRows && j <= numCols {
+			assignment[i-1] = j - 1
+		}
+	}
+	return assignment
+}
@@ -0,0 +1,118 @@
+//go:build !no_cgo
+
+package obstaclesdepth
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+)
+
+func TestIoU3D(t *testing.T) {
+	cases := []struct {
+		name                   string
+		minA, maxA, minB, maxB r3.Vector
+		want                   float64
+	}{
+		{
+			name: "identical boxes",
+			minA: r3.Vector{X: 0, Y: 0, Z: 0}, maxA: r3.Vector{X: 2, Y: 2, Z: 2},
+			minB: r3.Vector{X: 0, Y: 0, Z: 0}, maxB: r3.Vector{X: 2, Y: 2, Z: 2},
+			want: 1.0,
+		},
+		{
+			name: "disjoint boxes",
+			minA: r3.Vector{X: 0, Y: 0, Z: 0}, maxA: r3.Vector{X: 1, Y: 1, Z: 1},
+			minB: r3.Vector{X: 10, Y: 10, Z: 10}, maxB: r3.Vector{X: 11, Y: 11, Z: 11},
+			want: 0.0,
+		},
+		{
+			name: "half overlap along one axis",
+			minA: r3.Vector{X: 0, Y: 0, Z: 0}, maxA: r3.Vector{X: 2, Y: 2, Z: 2},
+			minB: r3.Vector{X: 1, Y: 0, Z: 0}, maxB: r3.Vector{X: 3, Y: 2, Z: 2},
+			want: 1.0 / 3.0,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := iou3D(c.minA, c.maxA, c.minB, c.maxB)
+			if math.Abs(got-c.want) > 1e-9 {
+				t.Errorf("iou3D() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestLerp(t *testing.T) {
+	a := r3.Vector{X: 0, Y: 0, Z: 0}
+	b := r3.Vector{X: 10, Y: 10, Z: 10}
+	got := lerp(a, b, 0.6)
+	want := r3.Vector{X: 6, Y: 6, Z: 6}
+	if got != want {
+		t.Errorf("lerp() = %v, want %v", got, want)
+	}
+}
+
+func TestSolveAssignmentSquare(t *testing.T) {
+	// Row 0 should match column 1, row 1 should match column 0.
+	cost := [][]float64{
+		{1, 0},
+		{0, 1},
+	}
+	got := solveAssignment(cost)
+	want := []int{1, 0}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("solveAssignment() = %v, want %v", got, want)
+	}
+}
+
+func TestSolveAssignmentMoreRowsThanColumns(t *testing.T) {
+	// Two rows competing for a single cheap column: only one can win it.
+	cost := [][]float64{
+		{0},
+		{0},
+	}
+	got := solveAssignment(cost)
+	if len(got) != 2 {
+		t.Fatalf("expected assignment of length 2, got %v", got)
+	}
+	matched := 0
+	for _, j := range got {
+		if j == 0 {
+			matched++
+		} else if j != -1 {
+			t.Errorf("unexpected column index %d", j)
+		}
+	}
+	if matched != 1 {
+		t.Errorf("expected exactly 1 row matched to column 0, got %d", matched)
+	}
+}
+
+func TestSolveAssignmentEmpty(t *testing.T) {
+	if got := solveAssignment(nil); got != nil {
+		t.Errorf("solveAssignment(nil) = %v, want nil", got)
+	}
+}
+
+func TestTrackingConfigCheckValid(t *testing.T) {
+	cases := []struct {
+		name    string
+		conf    TrackingConfig
+		wantErr bool
+	}{
+		{"valid", TrackingConfig{MaxAgeMs: 500, IoUThreshold: 0.3}, false},
+		{"zero max age", TrackingConfig{MaxAgeMs: 0, IoUThreshold: 0.3}, true},
+		{"zero iou threshold", TrackingConfig{MaxAgeMs: 500, IoUThreshold: 0}, true},
+		{"iou threshold above 1", TrackingConfig{MaxAgeMs: 500, IoUThreshold: 1.5}, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.conf.CheckValid()
+			if (err != nil) != c.wantErr {
+				t.Errorf("CheckValid() error = %v, wantErr %v", err, c.wantErr)
+			}
+		})
+	}
+}